@@ -0,0 +1,78 @@
+// Command zipserver exposes a zipcodes.Zipcodes dataset as an HTTP/JSON API.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/TerraTech/zipcodes"
+	"github.com/TerraTech/zipcodes/dataset"
+	"github.com/TerraTech/zipcodes/server"
+)
+
+func main() {
+	datasetPath := flag.String("dataset", "", "path to the GeoNames postal code dataset")
+	country := flag.String("country", "", "optional 2-letter ISO country code to filter the dataset by")
+	addr := flag.String("addr", ":8080", "address to listen on")
+	update := flag.Bool("update", false, "fetch the dataset before starting, re-downloading only if the local copy is older than -max-age")
+	maxAge := flag.Duration("max-age", 24*time.Hour, "maximum age of a locally cached dataset before -update re-downloads it")
+	flag.Parse()
+
+	if *datasetPath == "" {
+		log.Fatal("zipserver: -dataset is required")
+	}
+
+	if *update {
+		fetchCountry := *country
+		if fetchCountry == "" {
+			fetchCountry = "all"
+		}
+
+		path, err := dataset.EnsureDataset(filepath.Dir(*datasetPath), fetchCountry, *maxAge)
+		if err != nil {
+			log.Fatalf("zipserver: error updating dataset: %v", err)
+		}
+		datasetPath = &path
+	}
+
+	var (
+		zc  zipcodes.Zipcodes
+		err error
+	)
+	if *country != "" {
+		zc, err = zipcodes.NewByCountry(*datasetPath, *country)
+	} else {
+		zc, err = zipcodes.New(*datasetPath)
+	}
+	if err != nil {
+		log.Fatalf("zipserver: error loading dataset: %v", err)
+	}
+
+	srv := &http.Server{
+		Addr:    *addr,
+		Handler: server.New(zc),
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("zipserver: %v", err)
+		}
+	}()
+	log.Printf("zipserver: listening on %s", *addr)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	<-stop
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Fatalf("zipserver: error during shutdown: %v", err)
+	}
+}
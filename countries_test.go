@@ -0,0 +1,37 @@
+package zipcodes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewByCountries(t *testing.T) {
+	zipcodesDataset, err := NewByCountries("datasets/valid_dataset.txt", "DE")
+	if err != nil {
+		t.Errorf("Unexpected error while initializing struct %v", err)
+	}
+
+	zc, err := zipcodesDataset.LookupInCountry("DE", "01945")
+	if err != nil {
+		t.Errorf("Unexpected error while looking up zipcode %v", err)
+	}
+	if zc[0].CountryCode != "DE" {
+		t.Errorf("Unexpected country code. Got %s, want %s", zc[0].CountryCode, "DE")
+	}
+
+	// A zipcode that exists, but not in the requested country, should not
+	// be found.
+	_, err = zipcodesDataset.LookupInCountry("US", "01945")
+	if err != ErrZipcodeNotFound {
+		t.Errorf("Unexpected error looking up a zipcode in the wrong country. Got %v, want %v", err, ErrZipcodeNotFound)
+	}
+
+	// Lookup should still find it regardless of country, for back-compat.
+	legacy, err := zipcodesDataset.Lookup("01945")
+	if err != nil {
+		t.Errorf("Unexpected error while looking up zipcode %v", err)
+	}
+	if !reflect.DeepEqual(legacy, zc) {
+		t.Errorf("Unexpected mismatch between Lookup and LookupInCountry. Got %v, want %v", legacy, zc)
+	}
+}
@@ -0,0 +1,160 @@
+// Package dataset downloads and refreshes the GeoNames postal code archives
+// that zipcodes.LoadDataset and zipcodes.LoadDatasetByCountry read from, so
+// callers don't have to fetch and unzip them by hand.
+package dataset
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// baseURL is a var rather than a const so tests can point it at a local
+// httptest server instead of the real GeoNames site.
+var baseURL = "https://download.geonames.org/export/zip/"
+
+// FetchDataset downloads and unzips the GeoNames postal code archive for the
+// given 2-letter ISO country code (or "all" for every country) into
+// destDir, returning the path to the extracted dataset file ready for
+// zipcodes.LoadDataset or zipcodes.LoadDatasetByCountry.
+func FetchDataset(country, destDir string) (string, error) {
+	archiveName := datasetBaseName(country) + ".zip"
+
+	archivePath := filepath.Join(destDir, archiveName)
+	if err := downloadFile(baseURL+archiveName, archivePath); err != nil {
+		return "", err
+	}
+
+	return unzipDataset(archivePath, destDir)
+}
+
+// FetchAllCountries downloads and unzips the combined GeoNames postal code
+// archive covering every country into destDir.
+func FetchAllCountries(destDir string) error {
+	_, err := FetchDataset("all", destDir)
+	return err
+}
+
+// EnsureDataset returns the path to a dataset for country inside destDir,
+// fetching a fresh copy only if none exists yet or the local copy is older
+// than maxAge. This lets long-running services keep their dataset fresh
+// without re-downloading it on every restart.
+func EnsureDataset(destDir, country string, maxAge time.Duration) (string, error) {
+	datasetPath := filepath.Join(destDir, datasetBaseName(country)+".txt")
+
+	if info, err := os.Stat(datasetPath); err == nil && time.Since(info.ModTime()) < maxAge {
+		return datasetPath, nil
+	}
+
+	return FetchDataset(country, destDir)
+}
+
+// datasetBaseName returns the GeoNames archive/dataset base name for
+// country (e.g. "DE" or "allCountries" for "all"), shared by FetchDataset
+// and EnsureDataset so the archive FetchDataset downloads and the file
+// EnsureDataset probes for can never drift apart.
+func datasetBaseName(country string) string {
+	country = strings.ToUpper(country)
+	if country == "ALL" {
+		return "allCountries"
+	}
+	return country
+}
+
+// downloadFile downloads url into destPath, creating destPath's parent
+// directory if needed. GeoNames doesn't publish a checksum to verify the
+// archive against up front, so the only general check available here is
+// that the download actually produced bytes; that's a truncation guard,
+// not a checksum. The real checksum verification happens in unzipDataset:
+// reading a zip entry to EOF makes archive/zip validate that entry's
+// built-in CRC-32, which is what actually catches a corrupted download
+// (including one from a chunked, Content-Length-less response).
+func downloadFile(url, destPath string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("dataset: error downloading %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("dataset: unexpected status %s downloading %s", resp.Status, url)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return fmt.Errorf("dataset: error creating %s: %w", filepath.Dir(destPath), err)
+	}
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("dataset: error creating %s: %w", destPath, err)
+	}
+	defer out.Close()
+
+	written, err := io.Copy(out, resp.Body)
+	if err != nil {
+		return fmt.Errorf("dataset: error writing %s: %w", destPath, err)
+	}
+	if written == 0 {
+		return fmt.Errorf("dataset: downloaded an empty archive from %s", url)
+	}
+
+	return nil
+}
+
+// unzipDataset extracts the postal code file inside the archive at
+// archivePath into destDir and returns its path. archivePath itself is
+// never checksummed against an external value (see downloadFile), but
+// extractFile reads each entry to EOF, so archive/zip validates that
+// entry's CRC-32 checksum and returns zip.ErrChecksum on a corrupted
+// archive instead of silently extracting bad data.
+func unzipDataset(archivePath, destDir string) (string, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("dataset: error opening archive %s: %w", archivePath, err)
+	}
+	defer reader.Close()
+
+	var datasetPath string
+	for _, f := range reader.File {
+		if !strings.HasSuffix(f.Name, ".txt") || strings.EqualFold(f.Name, "readme.txt") {
+			continue
+		}
+
+		extractedPath := filepath.Join(destDir, f.Name)
+		if err := extractFile(f, extractedPath); err != nil {
+			return "", err
+		}
+		datasetPath = extractedPath
+	}
+
+	if datasetPath == "" {
+		return "", fmt.Errorf("dataset: archive %s did not contain a postal code file", archivePath)
+	}
+
+	return datasetPath, nil
+}
+
+func extractFile(f *zip.File, destPath string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("dataset: error reading %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("dataset: error creating %s: %w", destPath, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, rc); err != nil {
+		return fmt.Errorf("dataset: error writing %s: %w", destPath, err)
+	}
+
+	return nil
+}
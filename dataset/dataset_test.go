@@ -0,0 +1,143 @@
+package dataset
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newZipArchive builds an in-memory zip archive containing a single file,
+// mirroring the layout of a GeoNames postal code archive.
+func newZipArchive(t *testing.T, name, contents string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create(name)
+	if err != nil {
+		t.Fatalf("Unexpected error creating zip entry %v", err)
+	}
+	if _, err := f.Write([]byte(contents)); err != nil {
+		t.Fatalf("Unexpected error writing zip entry %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error closing zip writer %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// TestFetchDatasetCorruptedArchive guards against downloadFile's lack of an
+// external checksum silently producing a bad dataset: a download whose
+// bytes land wrong must still surface as an error, via archive/zip
+// rejecting the corrupted entry's CRC-32 when it's extracted.
+func TestFetchDatasetCorruptedArchive(t *testing.T) {
+	archive := newZipArchive(t, "DE.txt", "DE\t01945\tGuteborn\tBrandenburg\tBB\t\t\t\t51.4167\t13.9333\t\n")
+	corrupted := append([]byte(nil), archive...)
+	for i, b := range corrupted {
+		if b == 'G' {
+			corrupted[i] = 'g'
+			break
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(corrupted)
+	}))
+	defer server.Close()
+
+	origBaseURL := baseURL
+	baseURL = server.URL + "/"
+	defer func() { baseURL = origBaseURL }()
+
+	destDir := t.TempDir()
+
+	if _, err := FetchDataset("DE", destDir); err == nil {
+		t.Errorf("Expected a checksum error extracting a corrupted archive, got nil")
+	}
+}
+
+func TestEnsureDataset(t *testing.T) {
+	archive := newZipArchive(t, "DE.txt", "DE\t01945\tGuteborn\tBrandenburg\tBB\t\t\t\t51.4167\t13.9333\t\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	origBaseURL := baseURL
+	baseURL = server.URL + "/"
+	defer func() { baseURL = origBaseURL }()
+
+	destDir := t.TempDir()
+
+	path, err := EnsureDataset(destDir, "DE", time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error fetching dataset %v", err)
+	}
+	if filepath.Base(path) != "DE.txt" {
+		t.Errorf("Unexpected dataset path. Got %s, want a DE.txt file", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected dataset file to exist at %s: %v", path, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Unexpected error statting dataset %v", err)
+	}
+	modTimeBeforeRefetch := info.ModTime()
+
+	// A second call within maxAge should reuse the cached file rather than
+	// hitting the server again.
+	if _, err := EnsureDataset(destDir, "DE", time.Hour); err != nil {
+		t.Fatalf("Unexpected error re-checking dataset %v", err)
+	}
+	info, err = os.Stat(path)
+	if err != nil {
+		t.Fatalf("Unexpected error statting dataset %v", err)
+	}
+	if !info.ModTime().Equal(modTimeBeforeRefetch) {
+		t.Errorf("Expected cached dataset to be reused within maxAge")
+	}
+}
+
+// TestEnsureDatasetAllCountriesCacheHit guards against EnsureDataset probing
+// for a filename FetchDataset never produces: FetchDataset("all", …)
+// extracts allCountries.txt, so EnsureDataset must look for that, not
+// ALL.txt.
+func TestEnsureDatasetAllCountriesCacheHit(t *testing.T) {
+	requests := 0
+	archive := newZipArchive(t, "allCountries.txt", "DE\t01945\tGuteborn\tBrandenburg\tBB\t\t\t\t51.4167\t13.9333\t\n")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	origBaseURL := baseURL
+	baseURL = server.URL + "/"
+	defer func() { baseURL = origBaseURL }()
+
+	destDir := t.TempDir()
+
+	if _, err := EnsureDataset(destDir, "all", time.Hour); err != nil {
+		t.Fatalf("Unexpected error fetching dataset %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("Expected 1 request after the first fetch, got %d", requests)
+	}
+
+	if _, err := EnsureDataset(destDir, "all", time.Hour); err != nil {
+		t.Fatalf("Unexpected error re-checking dataset %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the cached allCountries.txt to be reused, but EnsureDataset re-fetched (requests=%d)", requests)
+	}
+}
@@ -0,0 +1,339 @@
+package zipcodes
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// geohashLatBits and geohashLonBits control the precision of the geohash
+// index: 26 bits per axis keeps the combined hash inside 64 bits while
+// giving sub-meter resolution, far finer than any radius query needs.
+const (
+	geohashLatBits = 26
+	geohashLonBits = 26
+)
+
+// geoIndexEntry pairs a geohash with the ZipCodeLocation it was derived
+// from, so a hash range lookup can report back the original data.
+type geoIndexEntry struct {
+	hash uint64
+	loc  *ZipCodeLocation
+}
+
+// geoIndex is a geohash bucket index: a sorted slice of interleaved lat/lon
+// geohashes. Radius and k-NN queries binary-search a handful of hash ranges
+// in this slice instead of scanning every ZipCodeLocation in DatasetList.
+type geoIndex struct {
+	entries []geoIndexEntry
+}
+
+// buildGeoIndex builds a geoIndex over every ZipCodeLocation in datasetList.
+func buildGeoIndex(datasetList map[string]ZipCodeLocations) *geoIndex {
+	idx := &geoIndex{}
+	for _, locations := range datasetList {
+		for i := range locations {
+			loc := &locations[i]
+			idx.entries = append(idx.entries, geoIndexEntry{
+				hash: encodeGeohash(loc.Lat, loc.Lon),
+				loc:  loc,
+			})
+		}
+	}
+	sort.Slice(idx.entries, func(i, j int) bool {
+		return idx.entries[i].hash < idx.entries[j].hash
+	})
+	return idx
+}
+
+// RebuildIndex rebuilds the spatial index from the current DatasetList. New
+// and LoadDataset already build the index while loading, so this is only
+// needed after DatasetList has been mutated directly.
+func (zc *Zipcodes) RebuildIndex() {
+	zc.index = buildGeoIndex(zc.DatasetList)
+}
+
+// encodeGeohash interleaves the bits of lat and lon into a single geohash,
+// following the usual base-32 geohash convention of interleaving longitude
+// into the even bits and latitude into the odd bits.
+func encodeGeohash(lat, lon float64) uint64 {
+	latBits := encodeAxis(lat, -90, 90, geohashLatBits)
+	lonBits := encodeAxis(lon, -180, 180, geohashLonBits)
+	return interleaveBits(latBits, lonBits)
+}
+
+// encodeAxis repeatedly bisects [min, max), recording which half value fell
+// into on each step, producing the bits geohash uses for a single axis.
+func encodeAxis(value, min, max float64, bits int) uint64 {
+	var out uint64
+	for i := 0; i < bits; i++ {
+		mid := (min + max) / 2
+		out <<= 1
+		if value >= mid {
+			out |= 1
+			min = mid
+		} else {
+			max = mid
+		}
+	}
+	return out
+}
+
+// interleaveBits combines geohashLatBits worth of latBits and lonBits into a
+// single hash, alternating longitude/latitude bits from most to least
+// significant.
+func interleaveBits(latBits, lonBits uint64) uint64 {
+	var hash uint64
+	for i := geohashLatBits - 1; i >= 0; i-- {
+		hash <<= 2
+		hash |= ((lonBits >> uint(i)) & 1) << 1
+		hash |= (latBits >> uint(i)) & 1
+	}
+	return hash
+}
+
+// cellLevel returns the number of bits per axis whose cell width is at
+// least radiusDeg degrees, so that the cells covering the query point's
+// neighborhood at that level are guaranteed to cover a circle of that
+// radius.
+func cellLevel(radiusDeg float64) int {
+	if radiusDeg <= 0 {
+		return geohashLatBits
+	}
+	level := int(math.Floor(math.Log2(180 / radiusDeg)))
+	if level < 0 {
+		level = 0
+	}
+	if level > geohashLatBits {
+		level = geohashLatBits
+	}
+	return level
+}
+
+// gridIndex maps value into one of 2^bits equal buckets spanning [min, max).
+func gridIndex(value, min, max float64, bits int) int {
+	cells := 1 << uint(bits)
+	idx := int((value - min) / (max - min) * float64(cells))
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= cells {
+		idx = cells - 1
+	}
+	return idx
+}
+
+// gridIndexRange returns the inclusive grid index range, at the given bit
+// depth, covering [value-delta, value+delta].
+func gridIndexRange(value, delta, min, max float64, bits int) (int, int) {
+	lo := clamp(value-delta, min, max)
+	hi := clamp(value+delta, min, max)
+	return gridIndex(lo, min, max, bits), gridIndex(hi, min, max, bits)
+}
+
+// lonDeltaForRadius converts a great-circle delta of radiusDeg degrees into
+// the longitude delta needed to cover it at the given latitude. Meridians
+// converge away from the equator, so a fixed radiusDeg window of longitude
+// covers less and less actual distance as |lat| grows. The circle's widest
+// longitude offset is asin(sin(radius)/cos(lat)); once the circle reaches a
+// pole it spans every longitude, so the delta is the full 180.
+func lonDeltaForRadius(radiusDeg, lat float64) float64 {
+	if math.Abs(lat)+radiusDeg >= 90 {
+		return 180
+	}
+	return math.Asin(math.Sin(degreesToRadians(radiusDeg))/math.Cos(degreesToRadians(lat))) * 180 / math.Pi
+}
+
+func clamp(value, min, max float64) float64 {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+// lonRange is an inclusive grid index range along the longitude axis.
+type lonRange struct {
+	lo, hi int
+}
+
+// lonRangesWithWrap returns the grid index ranges, at the given bit depth,
+// covering [lon-delta, lon+delta]. Longitude wraps at the antimeridian
+// (±180), unlike latitude, so a window that extends past -180 or 180 is
+// split into the two bands it actually covers once wrapped back into
+// range, instead of being silently clamped down to a single edge band.
+// lonDeltaForRadius caps delta at 180, so at most one side ever wraps. When
+// the two bands would share a cell, a single band of every cell is returned
+// instead, so no cell is searched twice.
+func lonRangesWithWrap(lon, delta float64, level int) []lonRange {
+	low := lon - delta
+	high := lon + delta
+	cells := 1 << uint(level)
+
+	if low >= -180 && high <= 180 {
+		return []lonRange{{gridIndex(low, -180, 180, level), gridIndex(high, -180, 180, level)}}
+	}
+
+	if low < -180 {
+		wrappedLo, hiIdx := gridIndex(low+360, -180, 180, level), gridIndex(high, -180, 180, level)
+		if wrappedLo <= hiIdx {
+			return []lonRange{{0, cells - 1}}
+		}
+		return []lonRange{{wrappedLo, cells - 1}, {0, hiIdx}}
+	}
+
+	loIdx, wrappedHi := gridIndex(low, -180, 180, level), gridIndex(high-360, -180, 180, level)
+	if wrappedHi >= loIdx {
+		return []lonRange{{0, cells - 1}}
+	}
+	return []lonRange{{loIdx, cells - 1}, {0, wrappedHi}}
+}
+
+// query returns every ZipCodeLocation whose geohash cell, at the precision
+// matching radiusDeg, falls within radiusDeg degrees of (lat, lon). Callers
+// still need to apply an exact Haversine check, since this only narrows the
+// search to the enclosing grid cells. Longitude wraps around the
+// antimeridian (and, since lonDeltaForRadius widens near the poles, the
+// window there ends up spanning the full circle), so the search isn't just
+// a single clamped band.
+func (idx *geoIndex) query(lat, lon, radiusDeg float64) []*ZipCodeLocation {
+	level := cellLevel(radiusDeg)
+	latLo, latHi := gridIndexRange(lat, radiusDeg, -90, 90, level)
+
+	latShift := uint(geohashLatBits - level)
+	lonShift := uint(geohashLonBits - level)
+	span := uint64(1) << uint(2*int(latShift))
+
+	var candidates []*ZipCodeLocation
+	for _, lr := range lonRangesWithWrap(lon, lonDeltaForRadius(radiusDeg, lat), level) {
+		for latIdx := latLo; latIdx <= latHi; latIdx++ {
+			for lonIdx := lr.lo; lonIdx <= lr.hi; lonIdx++ {
+				prefix := interleaveBits(uint64(latIdx)<<latShift, uint64(lonIdx)<<lonShift)
+				lo := sort.Search(len(idx.entries), func(i int) bool { return idx.entries[i].hash >= prefix })
+				hi := sort.Search(len(idx.entries), func(i int) bool { return idx.entries[i].hash >= prefix+span })
+				for _, entry := range idx.entries[lo:hi] {
+					candidates = append(candidates, entry.loc)
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// toCountrySet upper-cases countries into a membership set. An empty or nil
+// slice yields an empty set, which callers treat as "no filter".
+func toCountrySet(countries []string) map[string]bool {
+	set := make(map[string]bool, len(countries))
+	for _, country := range countries {
+		set[strings.ToUpper(country)] = true
+	}
+	return set
+}
+
+// findZipcodesWithinRadiusIndexed finds zipcodes within a given radius using
+// the geohash index, optionally narrowed down to one or more country codes,
+// falling back to a brute-force scan of DatasetList if no index has been
+// built (e.g. for a zero-value Zipcodes{}).
+func (zc Zipcodes) findZipcodesWithinRadiusIndexed(zipcodeLocation ZipCodeLocation, maxRadius, earthRadius float64, countries []string) []string {
+	countrySet := toCountrySet(countries)
+
+	var candidates []*ZipCodeLocation
+	if zc.index == nil {
+		for _, locations := range zc.DatasetList {
+			for i := range locations {
+				candidates = append(candidates, &locations[i])
+			}
+		}
+	} else {
+		radiusDeg := (maxRadius / earthRadius) * (180 / math.Pi)
+		candidates = zc.index.query(zipcodeLocation.Lat, zipcodeLocation.Lon, radiusDeg)
+	}
+
+	zipcodeList := []string{}
+	for _, c := range candidates {
+		if c.ZipCode == zipcodeLocation.ZipCode {
+			continue
+		}
+		if len(countrySet) > 0 && !countrySet[c.CountryCode] {
+			continue
+		}
+		distance := DistanceBetweenPoints(zipcodeLocation.Lat, zipcodeLocation.Lon, c.Lat, c.Lon, earthRadius)
+		if distance < maxRadius {
+			zipcodeList = append(zipcodeList, c.ZipCode)
+		}
+	}
+	return zipcodeList
+}
+
+// KNearestZipcodes returns the k ZipCodeLocations closest to the given
+// lat/lon, ordered from nearest to farthest. It starts at the finest geohash
+// precision level and backs off to coarser levels until at least k
+// candidates are found and the kth nearest of them lies within the radius
+// that level searches.
+func (zc Zipcodes) KNearestZipcodes(lat, lon float64, k int) []ZipCodeLocation {
+	return zc.kNearestZipcodes(lat, lon, k, nil)
+}
+
+// KNearestZipcodesInCountries is KNearestZipcodes narrowed down to one or
+// more country codes.
+func (zc Zipcodes) KNearestZipcodesInCountries(lat, lon float64, k int, countries ...string) []ZipCodeLocation {
+	return zc.kNearestZipcodes(lat, lon, k, countries)
+}
+
+func (zc Zipcodes) kNearestZipcodes(lat, lon float64, k int, countries []string) []ZipCodeLocation {
+	if zc.index == nil || k <= 0 {
+		return nil
+	}
+
+	countrySet := toCountrySet(countries)
+	for level := geohashLatBits; level >= 0; level-- {
+		radiusDeg := 180 / math.Pow(2, float64(level))
+		candidates := filterByCountry(zc.index.query(lat, lon, radiusDeg), countrySet)
+		if len(candidates) < k && level > 0 {
+			continue
+		}
+		// Only locations within radiusDeg are sure to be among the candidates,
+		// so a closer location may lie outside unless the kth is within it.
+		nearest := nearestFrom(candidates, lat, lon, k)
+		if level == 0 || DistanceBetweenPoints(lat, lon, nearest[k-1].Lat, nearest[k-1].Lon, earthRadiusKm) <= degreesToRadians(radiusDeg)*earthRadiusKm {
+			return nearest
+		}
+	}
+	return nil
+}
+
+// filterByCountry returns the subset of candidates whose CountryCode is in
+// countrySet. An empty countrySet means "no filter".
+func filterByCountry(candidates []*ZipCodeLocation, countrySet map[string]bool) []*ZipCodeLocation {
+	if len(countrySet) == 0 {
+		return candidates
+	}
+
+	filtered := candidates[:0]
+	for _, c := range candidates {
+		if countrySet[c.CountryCode] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// nearestFrom sorts candidates by true Haversine distance to (lat, lon) and
+// returns the closest k as ZipCodeLocation values.
+func nearestFrom(candidates []*ZipCodeLocation, lat, lon float64, k int) []ZipCodeLocation {
+	sort.Slice(candidates, func(i, j int) bool {
+		return DistanceBetweenPoints(lat, lon, candidates[i].Lat, candidates[i].Lon, earthRadiusKm) <
+			DistanceBetweenPoints(lat, lon, candidates[j].Lat, candidates[j].Lon, earthRadiusKm)
+	})
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+
+	result := make([]ZipCodeLocation, len(candidates))
+	for i, c := range candidates {
+		result[i] = *c
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package zipcodes
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGetZipcodesWithinKmRadiusMatchesBruteForce(t *testing.T) {
+	zipcodesDataset, err := New("datasets/valid_dataset.txt")
+	if err != nil {
+		t.Errorf("Unexpected error while initializing struct %v", err)
+	}
+
+	cases := []struct {
+		ZipCode string
+		Radius  float64
+	}{
+		{"01945", 50.0},
+		{"01945", 100.0},
+	}
+
+	for _, c := range cases {
+		zc, err := zipcodesDataset.Lookup(c.ZipCode)
+		if err != nil {
+			t.Error(err)
+		}
+
+		indexed := zipcodesDataset.GetZipcodesWithinKmRadius(zc[0], c.Radius)
+		bruteForce := zipcodesDataset.FindZipcodesWithinRadius(zc[0], c.Radius, earthRadiusKm)
+
+		sort.Strings(indexed)
+		sort.Strings(bruteForce)
+
+		if !reflect.DeepEqual(indexed, bruteForce) {
+			t.Errorf("Indexed radius query does not match brute force. Got %v, want %v", indexed, bruteForce)
+		}
+	}
+}
+
+// TestGetZipcodesWithinKmRadiusAtHighLatitude guards against the indexed
+// path under-covering longitude at non-equatorial latitudes, where a
+// radiusDeg window of longitude spans less great-circle distance than the
+// same window does of latitude.
+func TestGetZipcodesWithinKmRadiusAtHighLatitude(t *testing.T) {
+	origin := ZipCodeLocation{CountryCode: "NO", ZipCode: "10000", Lat: 60, Lon: 0.6}
+	neighbor := ZipCodeLocation{CountryCode: "NO", ZipCode: "20000", Lat: 60, Lon: 1.45}
+
+	zc := Zipcodes{
+		DatasetList: map[string]ZipCodeLocations{
+			"NO:10000": {origin},
+			"NO:20000": {neighbor},
+		},
+	}
+	zc.RebuildIndex()
+
+	indexed := zc.GetZipcodesWithinKmRadius(origin, 50.0)
+	bruteForce := zc.FindZipcodesWithinRadius(origin, 50.0, earthRadiusKm)
+
+	if !reflect.DeepEqual(indexed, bruteForce) {
+		t.Errorf("Indexed radius query does not match brute force at high latitude. Got %v, want %v", indexed, bruteForce)
+	}
+	if len(indexed) != 1 || indexed[0] != "20000" {
+		t.Errorf("Expected the indexed query to find the high-latitude neighbor. Got %v", indexed)
+	}
+}
+
+// TestGetZipcodesWithinKmRadiusAcrossAntimeridian guards against the
+// indexed path under-covering longitude near the antimeridian (lon ±180),
+// where a naive clamp of [lon-delta, lon+delta] to [-180,180] would drop
+// the wrapped-around half of the search window.
+func TestGetZipcodesWithinKmRadiusAcrossAntimeridian(t *testing.T) {
+	origin := ZipCodeLocation{CountryCode: "FJ", ZipCode: "10000", Lat: 0, Lon: 179.9}
+	neighbor := ZipCodeLocation{CountryCode: "FJ", ZipCode: "20000", Lat: 0, Lon: -179.9}
+
+	zc := Zipcodes{
+		DatasetList: map[string]ZipCodeLocations{
+			"FJ:10000": {origin},
+			"FJ:20000": {neighbor},
+		},
+	}
+	zc.RebuildIndex()
+
+	indexed := zc.GetZipcodesWithinKmRadius(origin, 50.0)
+	bruteForce := zc.FindZipcodesWithinRadius(origin, 50.0, earthRadiusKm)
+
+	if !reflect.DeepEqual(indexed, bruteForce) {
+		t.Errorf("Indexed radius query does not match brute force across the antimeridian. Got %v, want %v", indexed, bruteForce)
+	}
+	if len(indexed) != 1 || indexed[0] != "20000" {
+		t.Errorf("Expected the indexed query to find the neighbor across the antimeridian. Got %v", indexed)
+	}
+}
+
+func TestKNearestZipcodes(t *testing.T) {
+	zipcodesDataset, err := New("datasets/valid_dataset.txt")
+	if err != nil {
+		t.Errorf("Unexpected error while initializing struct %v", err)
+	}
+
+	origin, err := zipcodesDataset.Lookup("01945")
+	if err != nil {
+		t.Error(err)
+	}
+
+	nearest := zipcodesDataset.KNearestZipcodes(origin[0].Lat, origin[0].Lon, 2)
+	if len(nearest) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(nearest))
+	}
+	if nearest[0].ZipCode != "01945" {
+		t.Errorf("Expected the nearest result to be the origin zipcode itself, got %s", nearest[0].ZipCode)
+	}
+}
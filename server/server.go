@@ -0,0 +1,143 @@
+// Package server exposes a zipcodes.Zipcodes dataset behind a small
+// HTTP/JSON API, so the library can be used from non-Go services.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TerraTech/zipcodes"
+)
+
+// Server wraps a zipcodes.Zipcodes dataset as an http.Handler.
+type Server struct {
+	zc  zipcodes.Zipcodes
+	mux *http.ServeMux
+}
+
+// New builds a Server serving the given dataset.
+func New(zc zipcodes.Zipcodes) *Server {
+	s := &Server{zc: zc, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/zip/", s.handleZip)
+	s.mux.HandleFunc("/radius", s.handleRadius)
+	s.mux.HandleFunc("/nearest", s.handleNearest)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// distance is the distance between two ZipCodeLocations, in both units, as
+// returned by the /zip/{code}?distance={other} endpoint.
+type distance struct {
+	Km    float64 `json:"km"`
+	Miles float64 `json:"miles"`
+}
+
+type zipDistanceResponse struct {
+	Zip      zipcodes.ZipCodeLocation `json:"zip"`
+	Other    zipcodes.ZipCodeLocation `json:"other"`
+	Distance distance                 `json:"distance"`
+}
+
+// handleZip serves GET /zip/{code} and, with a ?distance={other} query
+// parameter, GET /zip/{code}?distance={other}&unit=km|mi.
+func (s *Server) handleZip(w http.ResponseWriter, r *http.Request) {
+	code := strings.TrimPrefix(r.URL.Path, "/zip/")
+	if code == "" {
+		http.Error(w, "missing zip code", http.StatusBadRequest)
+		return
+	}
+
+	locations, err := s.zc.Lookup(code)
+	switch {
+	case errors.Is(err, zipcodes.ErrZipcodeNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, zipcodes.ErrMultipleLatLon):
+		writeJSON(w, http.StatusMultipleChoices, locations)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	other := r.URL.Query().Get("distance")
+	if other == "" {
+		writeJSON(w, http.StatusOK, locations[0])
+		return
+	}
+
+	otherLocations, err := s.zc.Lookup(other)
+	if err != nil && !errors.Is(err, zipcodes.ErrMultipleLatLon) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, zipDistanceResponse{
+		Zip:   locations[0],
+		Other: otherLocations[0],
+		Distance: distance{
+			Km:    s.zc.DistanceInKm(locations[0], otherLocations[0]),
+			Miles: s.zc.DistanceInMiles(locations[0], otherLocations[0]),
+		},
+	})
+}
+
+// handleRadius serves GET /radius?zip={code}&radius={r}&unit=km|mi.
+func (s *Server) handleRadius(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	locations, err := s.zc.Lookup(q.Get("zip"))
+	if err != nil && !errors.Is(err, zipcodes.ErrMultipleLatLon) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	radius, err := strconv.ParseFloat(q.Get("radius"), 64)
+	if err != nil {
+		http.Error(w, "invalid radius", http.StatusBadRequest)
+		return
+	}
+
+	if q.Get("unit") == "mi" {
+		writeJSON(w, http.StatusOK, s.zc.GetZipcodesWithinMlRadius(locations[0], radius))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.zc.GetZipcodesWithinKmRadius(locations[0], radius))
+}
+
+// handleNearest serves GET /nearest?lat={}&lon={}&k={}.
+func (s *Server) handleNearest(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	lat, errLat := strconv.ParseFloat(q.Get("lat"), 64)
+	lon, errLon := strconv.ParseFloat(q.Get("lon"), 64)
+	if errLat != nil || errLon != nil {
+		http.Error(w, "invalid lat/lon", http.StatusBadRequest)
+		return
+	}
+
+	k := 1
+	if ks := q.Get("k"); ks != "" {
+		parsed, err := strconv.Atoi(ks)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid k", http.StatusBadRequest)
+			return
+		}
+		k = parsed
+	}
+
+	writeJSON(w, http.StatusOK, s.zc.KNearestZipcodes(lat, lon, k))
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
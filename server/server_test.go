@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TerraTech/zipcodes"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	zc, err := zipcodes.New("../datasets/valid_dataset.txt")
+	if err != nil {
+		t.Fatalf("Unexpected error while initializing dataset %v", err)
+	}
+	return New(zc)
+}
+
+func TestHandleZip(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/zip/01945", nil)
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var location zipcodes.ZipCodeLocation
+	if err := json.Unmarshal(rec.Body.Bytes(), &location); err != nil {
+		t.Fatalf("Unexpected error unmarshalling response %v", err)
+	}
+	if location.ZipCode != "01945" {
+		t.Errorf("Unexpected zip code. Got %s, want %s", location.ZipCode, "01945")
+	}
+}
+
+func TestHandleZipNotFound(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/zip/XYZ", nil)
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Unexpected status code. Got %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleZipDistance(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/zip/01945?distance=03058&unit=km", nil)
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var body zipDistanceResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error unmarshalling response %v", err)
+	}
+	if body.Distance.Km != 49.87 {
+		t.Errorf("Unexpected distance. Got %v, want %v", body.Distance.Km, 49.87)
+	}
+}
+
+func TestHandleRadius(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/radius?zip=01945&radius=50&unit=km", nil)
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var zips []string
+	if err := json.Unmarshal(rec.Body.Bytes(), &zips); err != nil {
+		t.Fatalf("Unexpected error unmarshalling response %v", err)
+	}
+	if len(zips) != 1 || zips[0] != "03058" {
+		t.Errorf("Unexpected radius response. Got %v", zips)
+	}
+}
+
+func TestHandleNearest(t *testing.T) {
+	srv := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/nearest?lat=51.4167&lon=13.9333&k=1", nil)
+	srv.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code. Got %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var locations []zipcodes.ZipCodeLocation
+	if err := json.Unmarshal(rec.Body.Bytes(), &locations); err != nil {
+		t.Fatalf("Unexpected error unmarshalling response %v", err)
+	}
+	if len(locations) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(locations))
+	}
+}
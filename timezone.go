@@ -0,0 +1,113 @@
+package zipcodes
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// LoadDatasetWithTimezones reads and loads the dataset the same way as
+// LoadDataset, then joins each ZipCodeLocation with a TimeZone looked up
+// from tzPath by country code. tzPath is GeoNames' own timeZones.txt
+// (https://download.geonames.org/export/dump/timeZones.txt): a header row
+// followed by "CountryCode\tTimeZoneId\tGMT offset\tDST offset\tRawOffset"
+// rows. That file only goes down to country granularity, not admin code,
+// so a country listed more than once (the US has six rows, one per zone)
+// only contributes its first row's zone here; per-admin precision would
+// need GeoNames' main gazetteer instead, which carries both an admin1 code
+// and a time zone per place. Callers who don't need TimeZone can keep using
+// LoadDataset, since joining the time zone file is opt-in.
+func LoadDatasetWithTimezones(datasetPath, tzPath string) (Zipcodes, error) {
+	zc, err := loadDataset(datasetPath, nil)
+	if err != nil {
+		return Zipcodes{}, err
+	}
+
+	timeZones, err := loadTimeZones(tzPath)
+	if err != nil {
+		return Zipcodes{}, err
+	}
+
+	applyTimeZones(zc.DatasetList, timeZones)
+	applyTimeZones(zc.byZip, timeZones)
+
+	return zc, nil
+}
+
+// loadTimeZones reads tzPath's rows into a lookup keyed by CountryCode. See
+// LoadDatasetWithTimezones for the expected file format and its
+// country-level (not admin-level) granularity.
+func loadTimeZones(tzPath string) (map[string]string, error) {
+	file, err := os.Open(tzPath)
+	if err != nil {
+		return nil, fmt.Errorf("zipcodes: error while opening timezone file %v", err)
+	}
+	defer file.Close()
+
+	timeZones := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	firstLine := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if firstLine {
+			firstLine = false
+			if strings.HasPrefix(line, "CountryCode\t") {
+				continue
+			}
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("zipcodes: timezone file line does not have a country code and time zone id")
+		}
+
+		country := strings.ToUpper(fields[0])
+		if _, seen := timeZones[country]; !seen {
+			timeZones[country] = fields[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("zipcodes: error while reading timezone file %v", err)
+	}
+
+	return timeZones, nil
+}
+
+// applyTimeZones sets TimeZone on every ZipCodeLocation in datasetList,
+// looked up by CountryCode.
+func applyTimeZones(datasetList map[string]ZipCodeLocations, timeZones map[string]string) {
+	for _, locations := range datasetList {
+		for i := range locations {
+			locations[i].TimeZone = timeZones[locations[i].CountryCode]
+		}
+	}
+}
+
+// LocationFor returns the *time.Location for a zipcode's TimeZone, loaded
+// via time.LoadLocation. It requires a dataset loaded with
+// LoadDatasetWithTimezones, since TimeZone is otherwise empty.
+func (zc Zipcodes) LocationFor(zipCode string) (*time.Location, error) {
+	locations, err := zc.Lookup(zipCode)
+	if err != nil && err != ErrMultipleLatLon {
+		return nil, err
+	}
+
+	timeZone := locations[0].TimeZone
+	if timeZone == "" {
+		return nil, fmt.Errorf("zipcodes: zipcode %s has no time zone; load the dataset with LoadDatasetWithTimezones", zipCode)
+	}
+
+	return time.LoadLocation(timeZone)
+}
+
+// LocalNow returns the current time in the given zipcode's local time zone.
+func (zc Zipcodes) LocalNow(zipCode string) (time.Time, error) {
+	loc, err := zc.LocationFor(zipCode)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Now().In(loc), nil
+}
@@ -0,0 +1,84 @@
+package zipcodes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestTimeZoneFile writes a file in the format of GeoNames' own
+// timeZones.txt: a header row followed by
+// "CountryCode\tTimeZoneId\tGMT offset\tDST offset\tRawOffset" rows. DE
+// only has one row, but the US's two here exercise that a country with
+// more than one zone keeps the first one it sees.
+func writeTestTimeZoneFile(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "timeZones.txt")
+	contents := "CountryCode\tTimeZoneId\tGMT offset\tDST offset\tRawOffset\n" +
+		"DE\tEurope/Berlin\t1.0\t2.0\t1.0\n" +
+		"US\tAmerica/New_York\t-5.0\t-4.0\t-5.0\n" +
+		"US\tAmerica/Chicago\t-6.0\t-5.0\t-6.0\n"
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("Unexpected error writing test timezone file %v", err)
+	}
+	return path
+}
+
+func TestLoadDatasetWithTimezones(t *testing.T) {
+	tzPath := writeTestTimeZoneFile(t)
+
+	zipcodesDataset, err := LoadDatasetWithTimezones("datasets/valid_dataset.txt", tzPath)
+	if err != nil {
+		t.Errorf("Unexpected error while initializing struct %v", err)
+	}
+
+	zc, err := zipcodesDataset.Lookup("01945")
+	if err != nil {
+		t.Errorf("Unexpected error while looking up zipcode %v", err)
+	}
+	if zc[0].TimeZone != "Europe/Berlin" {
+		t.Errorf("Unexpected time zone. Got %s, want %s", zc[0].TimeZone, "Europe/Berlin")
+	}
+}
+
+func TestLocationFor(t *testing.T) {
+	tzPath := writeTestTimeZoneFile(t)
+
+	zipcodesDataset, err := LoadDatasetWithTimezones("datasets/valid_dataset.txt", tzPath)
+	if err != nil {
+		t.Errorf("Unexpected error while initializing struct %v", err)
+	}
+
+	loc, err := zipcodesDataset.LocationFor("01945")
+	if err != nil {
+		t.Errorf("Unexpected error resolving location %v", err)
+	}
+	if loc.String() != "Europe/Berlin" {
+		t.Errorf("Unexpected location. Got %s, want %s", loc.String(), "Europe/Berlin")
+	}
+}
+
+func TestLoadTimeZonesKeepsFirstRowPerCountry(t *testing.T) {
+	tzPath := writeTestTimeZoneFile(t)
+
+	timeZones, err := loadTimeZones(tzPath)
+	if err != nil {
+		t.Fatalf("Unexpected error loading timezone file %v", err)
+	}
+
+	if got := timeZones["US"]; got != "America/New_York" {
+		t.Errorf("Expected a multi-zone country to keep its first row's zone. Got %s, want %s", got, "America/New_York")
+	}
+}
+
+func TestLocationForWithoutTimeZone(t *testing.T) {
+	zipcodesDataset, err := New("datasets/valid_dataset.txt")
+	if err != nil {
+		t.Errorf("Unexpected error while initializing struct %v", err)
+	}
+
+	if _, err := zipcodesDataset.LocationFor("01945"); err == nil {
+		t.Errorf("Expected an error resolving location for a dataset without time zones")
+	}
+}
@@ -25,12 +25,18 @@ var (
 
 // ZipCodeLocation struct represents each line of the dataset
 type ZipCodeLocation struct {
-	ZipCode   string
-	PlaceName string
-	AdminName string
-	Lat       float64
-	Lon       float64
-	StateCode string
+	CountryCode string  `json:"country_code"`
+	ZipCode     string  `json:"zip_code"`
+	PlaceName   string  `json:"place_name"`
+	AdminName   string  `json:"admin_name"`
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+	StateCode   string  `json:"state_code"`
+
+	// TimeZone is the IANA time zone name (e.g. "America/Los_Angeles") for
+	// this location. It is only populated when the dataset was loaded with
+	// LoadDatasetWithTimezones; otherwise it is empty.
+	TimeZone string `json:"time_zone,omitempty"`
 }
 
 // ZipCodeLocations slice represents a zipcode with multiple lat/lon coordinates
@@ -38,7 +44,21 @@ type ZipCodeLocations []ZipCodeLocation
 
 // Zipcodes contains the whole list of structs representing the zipcode dataset
 type Zipcodes struct {
+	// DatasetList is keyed by "CC:ZIP" (e.g. "DE:01945") so that zipcodes
+	// from different countries never collide. Use Lookup for the legacy,
+	// country-agnostic lookup or LookupInCountry to key off "CC:ZIP"
+	// directly.
 	DatasetList map[string]ZipCodeLocations
+
+	// byZip indexes the same ZipCodeLocations as DatasetList, keyed only by
+	// zipcode, to back the country-agnostic Lookup.
+	byZip map[string]ZipCodeLocations
+
+	// index is a geohash spatial index over DatasetList, built by New and
+	// LoadDataset, that GetZipcodesWithinKmRadius, GetZipcodesWithinMlRadius
+	// and KNearestZipcodes use to avoid a full scan. It is nil for a
+	// zero-value Zipcodes{}.
+	index *geoIndex
 }
 
 // New loads the dataset and returns a struct that contains the dataset as a map interface
@@ -52,9 +72,31 @@ func NewByCountry(datasetPath, country string) (Zipcodes, error) {
 	return LoadDatasetByCountry(datasetPath, country)
 }
 
-// Lookup looks for a zipcode inside the map interface
+// NewByCountries loads the dataset, filtered to the given set of country
+// codes, and returns a struct that contains the dataset as a map interface.
+func NewByCountries(datasetPath string, countries ...string) (Zipcodes, error) {
+	return LoadDatasetByCountries(datasetPath, countries...)
+}
+
+// Lookup looks for a zipcode inside the map interface, regardless of which
+// country it belongs to. If the same zipcode exists in more than one
+// country, or more than once within a country, it returns ErrMultipleLatLon
+// alongside every match; use LookupInCountry to disambiguate by country.
 func (zc Zipcodes) Lookup(zipCode string) (ZipCodeLocations, error) {
-	zipcodes, exists := zc.DatasetList[zipCode]
+	zipcodes, exists := zc.byZip[zipCode]
+	if !exists {
+		return nil, ErrZipcodeNotFound
+	} else if len(zipcodes) > 1 {
+		return zipcodes, ErrMultipleLatLon
+	} else {
+		return zipcodes, nil
+	}
+}
+
+// LookupInCountry looks for a zipcode within a specific country inside the
+// map interface.
+func (zc Zipcodes) LookupInCountry(country, zipCode string) (ZipCodeLocations, error) {
+	zipcodes, exists := zc.DatasetList[strings.ToUpper(country)+":"+zipCode]
 	if !exists {
 		return nil, ErrZipcodeNotFound
 	} else if len(zipcodes) > 1 {
@@ -89,17 +131,36 @@ func (zc Zipcodes) DistanceInMilToZipCode(zipcodeLocation ZipCodeLocation, latit
 	return DistanceBetweenPoints(zipcodeLocation.Lat, zipcodeLocation.Lon, latitude, longitude, earthRadiusMi)
 }
 
-// GetZipcodesWithinKmRadius get all zipcodes within the radius of this zipcode
+// GetZipcodesWithinKmRadius get all zipcodes within the radius of this
+// zipcode. It is backed by the geohash spatial index, so it runs in roughly
+// O(log N + result) instead of scanning the whole dataset.
 func (zc Zipcodes) GetZipcodesWithinKmRadius(zipcodeLocation ZipCodeLocation, radius float64) []string {
-	return zc.FindZipcodesWithinRadius(zipcodeLocation, radius, earthRadiusKm)
+	return zc.findZipcodesWithinRadiusIndexed(zipcodeLocation, radius, earthRadiusKm, nil)
 }
 
-// GetZipcodesWithinMlRadius get all zipcodes within the radius of this zipcode
+// GetZipcodesWithinMlRadius get all zipcodes within the radius of this
+// zipcode. It is backed by the geohash spatial index, so it runs in roughly
+// O(log N + result) instead of scanning the whole dataset.
 func (zc Zipcodes) GetZipcodesWithinMlRadius(zipcodeLocation ZipCodeLocation, radius float64) []string {
-	return zc.FindZipcodesWithinRadius(zipcodeLocation, radius, earthRadiusMi)
+	return zc.findZipcodesWithinRadiusIndexed(zipcodeLocation, radius, earthRadiusMi, nil)
 }
 
-// FindZipcodesWithinRadius finds zipcodes within a given radius
+// GetZipcodesWithinKmRadiusInCountries is GetZipcodesWithinKmRadius narrowed
+// down to one or more country codes.
+func (zc Zipcodes) GetZipcodesWithinKmRadiusInCountries(zipcodeLocation ZipCodeLocation, radius float64, countries ...string) []string {
+	return zc.findZipcodesWithinRadiusIndexed(zipcodeLocation, radius, earthRadiusKm, countries)
+}
+
+// GetZipcodesWithinMlRadiusInCountries is GetZipcodesWithinMlRadius narrowed
+// down to one or more country codes.
+func (zc Zipcodes) GetZipcodesWithinMlRadiusInCountries(zipcodeLocation ZipCodeLocation, radius float64, countries ...string) []string {
+	return zc.findZipcodesWithinRadiusIndexed(zipcodeLocation, radius, earthRadiusMi, countries)
+}
+
+// FindZipcodesWithinRadius finds zipcodes within a given radius by scanning
+// the whole DatasetList. It is kept as the brute-force reference
+// implementation that findZipcodesWithinRadiusIndexed is checked against;
+// prefer GetZipcodesWithinKmRadius/GetZipcodesWithinMlRadius for lookups.
 func (zc Zipcodes) FindZipcodesWithinRadius(zipcodeLocation ZipCodeLocation, maxRadius, earthRadius float64) []string {
 	zipcodeList := []string{}
 	for _, elm := range zc.DatasetList {
@@ -140,12 +201,21 @@ func DistanceBetweenPoints(latitude1, longitude1, latitude2, longitude2, radius
 
 // LoadDataset reads and loads the dataset into a map interface
 func LoadDataset(datasetPath string) (Zipcodes, error) {
-	return loadDataset(datasetPath, "")
+	return loadDataset(datasetPath, nil)
 }
 
 // LoadDatasetByCountry reads and loads the dataset into a map interface filtered by ISO Country Code
 func LoadDatasetByCountry(datasetPath, country string) (Zipcodes, error) {
-	return loadDataset(datasetPath, country)
+	if country == "" {
+		return loadDataset(datasetPath, nil)
+	}
+	return loadDataset(datasetPath, []string{country})
+}
+
+// LoadDatasetByCountries reads and loads the dataset into a map interface
+// filtered by a set of ISO Country Codes.
+func LoadDatasetByCountries(datasetPath string, countries ...string) (Zipcodes, error) {
+	return loadDataset(datasetPath, countries)
 }
 
 // IsMulti returns if there are multiple lat/lon coordinates for a zipcode
@@ -162,16 +232,29 @@ func hsin(t float64) float64 {
 	return math.Pow(math.Sin(t/2), 2)
 }
 
-// loadDataset is a consilidated function handling LoadDataset() and LoadDatasetByCountry()
-func loadDataset(datasetPath, country string) (Zipcodes, error) {
-	wantCountry := country != ""
+// loadDataset is a consilidated function handling LoadDataset(),
+// LoadDatasetByCountry() and LoadDatasetByCountries(). An empty countries
+// slice means every country in the dataset is loaded.
+func loadDataset(datasetPath string, countries []string) (Zipcodes, error) {
+	wantCountries := len(countries) > 0
 	inCountry := false
 
-	if wantCountry && len(country) != 2 {
-		return Zipcodes{}, fmt.Errorf("country must be a 2 character ISO Country Code")
+	countrySet := make(map[string]bool, len(countries))
+	for _, country := range countries {
+		if len(country) != 2 {
+			return Zipcodes{}, fmt.Errorf("country must be a 2 character ISO Country Code")
+		}
+		countrySet[strings.ToUpper(country)] = true
 	}
 
-	country = strings.ToUpper(country)
+	// Datasets are sorted by country, so when a single country is requested
+	// we can stop as soon as its contiguous block of lines ends.
+	singleCountry := ""
+	if len(countries) == 1 {
+		for country := range countrySet {
+			singleCountry = country
+		}
+	}
 
 	file, err := os.Open(datasetPath)
 	if err != nil {
@@ -181,14 +264,18 @@ func loadDataset(datasetPath, country string) (Zipcodes, error) {
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
-	zipcodeMap := Zipcodes{DatasetList: make(map[string]ZipCodeLocations)}
+	zipcodeMap := Zipcodes{
+		DatasetList: make(map[string]ZipCodeLocations),
+		byZip:       make(map[string]ZipCodeLocations),
+	}
 	for scanner.Scan() {
 		splittedLine := strings.Split(scanner.Text(), "\t")
 		if len(splittedLine) != 12 {
 			return Zipcodes{}, fmt.Errorf("zipcodes: file line does not have 12 fields")
 		}
 
-		if !wantCountry || splittedLine[0] == country {
+		country := splittedLine[0]
+		if !wantCountries || countrySet[country] {
 			inCountry = true
 
 			lat, errLat := strconv.ParseFloat(splittedLine[9], 64)
@@ -201,16 +288,20 @@ func loadDataset(datasetPath, country string) (Zipcodes, error) {
 				return Zipcodes{}, fmt.Errorf("zipcodes: error while converting %s to Longitude", splittedLine[10])
 			}
 
-			zipcodeMap.DatasetList[splittedLine[1]] =
-				append(zipcodeMap.DatasetList[splittedLine[1]], ZipCodeLocation{
-					ZipCode:   splittedLine[1],
-					PlaceName: splittedLine[2],
-					AdminName: splittedLine[3],
-					Lat:       lat,
-					Lon:       lon,
-					StateCode: splittedLine[4],
-				})
-		} else if inCountry && splittedLine[0] != country {
+			location := ZipCodeLocation{
+				CountryCode: country,
+				ZipCode:     splittedLine[1],
+				PlaceName:   splittedLine[2],
+				AdminName:   splittedLine[3],
+				Lat:         lat,
+				Lon:         lon,
+				StateCode:   splittedLine[4],
+			}
+
+			key := country + ":" + splittedLine[1]
+			zipcodeMap.DatasetList[key] = append(zipcodeMap.DatasetList[key], location)
+			zipcodeMap.byZip[splittedLine[1]] = append(zipcodeMap.byZip[splittedLine[1]], location)
+		} else if inCountry && singleCountry != "" && country != singleCountry {
 			break
 		}
 	}
@@ -219,5 +310,7 @@ func loadDataset(datasetPath, country string) (Zipcodes, error) {
 		return Zipcodes{}, fmt.Errorf("zipcodes: error while opening file %v", err)
 	}
 
+	zipcodeMap.index = buildGeoIndex(zipcodeMap.DatasetList)
+
 	return zipcodeMap, nil
 }
@@ -65,12 +65,13 @@ func TestLookup(t *testing.T) {
 		t.Errorf("Unexpected error while looking for zipcode %s", existingZipCode)
 	}
 	expectedZipCode := ZipCodeLocation{
-		ZipCode:   "01945",
-		PlaceName: "Guteborn",
-		AdminName: "Brandenburg",
-		Lat:       51.4167,
-		Lon:       13.9333,
-		StateCode: "BB",
+		CountryCode: "DE",
+		ZipCode:     "01945",
+		PlaceName:   "Guteborn",
+		AdminName:   "Brandenburg",
+		Lat:         51.4167,
+		Lon:         13.9333,
+		StateCode:   "BB",
 	}
 
 	if reflect.DeepEqual(foundedZC[0], expectedZipCode) != true {